@@ -0,0 +1,139 @@
+// Copyright Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package util
+
+import (
+	"crypto/x509"
+	"fmt"
+	"math/big"
+	"net"
+	"net/url"
+	"time"
+)
+
+// SupportedECSignatureAlgorithms are the signature algorithms that CertOptions.ECSigAlg may be set to in
+// order to select a non-RSA key type for GenCSR.
+type SupportedECSignatureAlgorithms string
+
+const (
+	// EcdsaSigAlg selects an ECDSA key, with the curve controlled by CertOptions.ECCCurve.
+	EcdsaSigAlg SupportedECSignatureAlgorithms = "ECDSA"
+
+	// Ed25519SigAlg selects an Ed25519 key. Ed25519 has a fixed key size, so CertOptions.ECCCurve
+	// and CertOptions.RSAKeySize are both ignored when this is set.
+	Ed25519SigAlg SupportedECSignatureAlgorithms = "ED25519"
+)
+
+// SupportedEllipticCurves are the curves that CertOptions.ECCCurve may be set to when ECSigAlg is EcdsaSigAlg.
+type SupportedEllipticCurves string
+
+const (
+	// P256Curve is the default curve used when ECCCurve is unset.
+	P256Curve SupportedEllipticCurves = "P256"
+	P384Curve SupportedEllipticCurves = "P384"
+	P521Curve SupportedEllipticCurves = "P521"
+	P224Curve SupportedEllipticCurves = "P224"
+)
+
+// CertOptions contains options for generating a new certificate or CSR.
+type CertOptions struct {
+	// Host is the comma-separated hostnames and IPs to generate a certificate for.
+	// This can also be set to the identity running the workload, like the Kubernetes
+	// service account.
+	Host string
+
+	// Org is the organization for this certificate.
+	Org string
+
+	// RSAKeySize is the size of the RSA private key to be generated. Ignored unless
+	// ECSigAlg is unset.
+	RSAKeySize int
+
+	// ECSigAlg selects a non-RSA key/signature algorithm for GenCSR. If unset, an RSA
+	// key of size RSAKeySize is generated instead.
+	ECSigAlg SupportedECSignatureAlgorithms
+
+	// ECCCurve selects the elliptic curve to use when ECSigAlg is EcdsaSigAlg. Defaults
+	// to P256Curve when unset.
+	ECCCurve SupportedEllipticCurves
+
+	// IsDualUse indicates whether the generated certificate is for dual-use client and
+	// server, in which case the first host is also set as the CommonName.
+	IsDualUse bool
+
+	// PKCS8Key indicates whether to encode the generated private key in PKCS#8 format,
+	// rather than the key-type-specific format (PKCS#1 for RSA, SEC1 for ECDSA).
+	PKCS8Key bool
+
+	// KeyProvider generates and, where possible, exports the private key backing GenCSR. When
+	// unset, DefaultKeyProvider is used, matching GenCSR's original in-process key generation.
+	KeyProvider KeyProvider
+
+	// IsCA indicates, for GenSelfSignedCert, whether the generated certificate may sign other
+	// certificates. When true, KeyUsageCertSign and KeyUsageCRLSign are added automatically.
+	IsCA bool
+
+	// NotBefore is the validity start time for GenSelfSignedCert. Defaults to time.Now() when zero.
+	NotBefore time.Time
+
+	// NotAfter is the validity end time for GenSelfSignedCert. Defaults to NotBefore plus one year
+	// when zero.
+	NotAfter time.Time
+
+	// SerialNumber is the certificate serial number for GenSelfSignedCert. A random 128-bit serial
+	// is generated when unset.
+	SerialNumber *big.Int
+
+	// KeyUsage is the key usage bitmask for GenSelfSignedCert. Defaults to
+	// KeyUsageDigitalSignature|KeyUsageKeyEncipherment when unset.
+	KeyUsage x509.KeyUsage
+
+	// ExtKeyUsage is the extended key usage list for GenSelfSignedCert.
+	ExtKeyUsage []x509.ExtKeyUsage
+
+	// DNSNames are additional dNSName SANs, merged with any inferred from Host.
+	DNSNames []string
+
+	// EmailAddresses are additional rfc822Name SANs, merged with any inferred from Host.
+	EmailAddresses []string
+
+	// IPAddresses are additional iPAddress SANs, merged with any inferred from Host.
+	IPAddresses []net.IP
+
+	// URIs are additional uniformResourceIdentifier SANs - for example SPIFFE identities - merged
+	// with any inferred from Host.
+	URIs []*url.URL
+}
+
+// ValidateCertOptions checks that the curve/algorithm/keysize combination in options is one that
+// GenCSR can act on, so that callers can fail fast before generating a key rather than after.
+func ValidateCertOptions(options CertOptions) error {
+	switch options.ECSigAlg {
+	case "":
+		if options.RSAKeySize < MinimumRsaKeySize {
+			return fmt.Errorf("requested RSA key size does not meet the minimum required size of %d (requested: %d)",
+				MinimumRsaKeySize, options.RSAKeySize)
+		}
+	case EcdsaSigAlg:
+		if _, err := ellipticCurve(options.ECCCurve); err != nil {
+			return err
+		}
+	case Ed25519SigAlg:
+		// Ed25519 has a fixed key size; ECCCurve and RSAKeySize are ignored.
+	default:
+		return fmt.Errorf("unsupported EC signature algorithm: %v", options.ECSigAlg)
+	}
+	return nil
+}
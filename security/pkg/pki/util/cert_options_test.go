@@ -0,0 +1,77 @@
+// Copyright Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package util
+
+import "testing"
+
+func TestEllipticCurve(t *testing.T) {
+	cases := []struct {
+		name    string
+		curve   SupportedEllipticCurves
+		wantErr bool
+	}{
+		{name: "default", curve: ""},
+		{name: "P256", curve: P256Curve},
+		{name: "P384", curve: P384Curve},
+		{name: "P521", curve: P521Curve},
+		{name: "P224", curve: P224Curve},
+		{name: "unrecognized", curve: "P999", wantErr: true},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			curve, err := ellipticCurve(c.curve)
+			if c.wantErr {
+				if err == nil {
+					t.Fatalf("ellipticCurve(%q): expected an error, got nil", c.curve)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ellipticCurve(%q): unexpected error: %v", c.curve, err)
+			}
+			if curve == nil {
+				t.Fatalf("ellipticCurve(%q): expected a non-nil curve", c.curve)
+			}
+		})
+	}
+}
+
+func TestValidateCertOptions(t *testing.T) {
+	cases := []struct {
+		name    string
+		options CertOptions
+		wantErr bool
+	}{
+		{name: "valid RSA", options: CertOptions{RSAKeySize: MinimumRsaKeySize}},
+		{name: "RSA key too small", options: CertOptions{RSAKeySize: 1024}, wantErr: true},
+		{name: "valid ECDSA", options: CertOptions{ECSigAlg: EcdsaSigAlg, ECCCurve: P384Curve}},
+		{name: "ECDSA unrecognized curve", options: CertOptions{ECSigAlg: EcdsaSigAlg, ECCCurve: "P999"}, wantErr: true},
+		{name: "valid Ed25519", options: CertOptions{ECSigAlg: Ed25519SigAlg}},
+		{name: "unsupported signature algorithm", options: CertOptions{ECSigAlg: "bogus"}, wantErr: true},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			err := ValidateCertOptions(c.options)
+			if c.wantErr && err == nil {
+				t.Fatal("expected an error, got nil")
+			}
+			if !c.wantErr && err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,140 @@
+// Copyright Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Provides utility methods to generate self-signed X.509 certificates. This implementation is
+// largely inspired from https://golang.org/src/crypto/tls/generate_cert.go.
+
+package util
+
+import (
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"math/big"
+	"time"
+
+	"istio.io/istio/pkg/log"
+)
+
+// maxSerialNumber is the upper bound (exclusive) used to generate a random 128-bit serial number.
+var maxSerialNumber = new(big.Int).Lsh(big.NewInt(1), 128)
+
+// defaultSelfSignedTTL is the validity duration used for GenSelfSignedCert when options.NotAfter
+// is unset.
+const defaultSelfSignedTTL = 365 * 24 * time.Hour
+
+// GenSelfSignedCert generates a self-signed X.509 certificate and private key with the given
+// options. Unlike GenCSR, which only produces a CSR for submission to a CA, this directly mints a
+// certificate - useful for bootstrapping a CA's own root/intermediate certificate, or for test
+// fixtures that just need a working cert/key pair.
+func GenSelfSignedCert(options CertOptions) (certPEM, keyPEM []byte, err error) {
+	provider := options.KeyProvider
+	if provider == nil {
+		provider = DefaultKeyProvider{}
+	}
+
+	signer, handle, err := provider.GenerateKey(options)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	template, err := genSelfSignedCertTemplate(options)
+	if err != nil {
+		return nil, nil, fmt.Errorf("self-signed certificate template creation failed (%v)", err)
+	}
+
+	certBytes, err := x509.CreateCertificate(rand.Reader, template, template, signer.Public(), signer)
+	if err != nil {
+		return nil, nil, fmt.Errorf("self-signed certificate creation failed (%v)", err)
+	}
+	certPEM = pem.EncodeToMemory(&pem.Block{Type: blockTypeCertificate, Bytes: certBytes})
+
+	keyPEM, err = provider.MarshalPrivate(handle)
+	if errors.Is(err, ErrPrivateKeyNotExportable) {
+		// The provider generated the key (e.g. inside an HSM) by design without ever handing us
+		// exportable material. The certificate itself is still valid and signed, so return it with
+		// no key.
+		return certPEM, nil, nil
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return certPEM, keyPEM, nil
+}
+
+// genSelfSignedCertTemplate builds the x509.Certificate template for GenSelfSignedCert, applying
+// the same host/org/SAN handling as GenCSRTemplate plus the CA-specific fields.
+func genSelfSignedCertTemplate(options CertOptions) (*x509.Certificate, error) {
+	serialNumber := options.SerialNumber
+	if serialNumber == nil {
+		var err error
+		serialNumber, err = rand.Int(rand.Reader, maxSerialNumber)
+		if err != nil {
+			return nil, fmt.Errorf("failed to generate serial number (%v)", err)
+		}
+	}
+
+	notBefore := options.NotBefore
+	if notBefore.IsZero() {
+		notBefore = time.Now()
+	}
+	notAfter := options.NotAfter
+	if notAfter.IsZero() {
+		notAfter = notBefore.Add(defaultSelfSignedTTL)
+	}
+
+	keyUsage := options.KeyUsage
+	if keyUsage == 0 {
+		keyUsage = x509.KeyUsageDigitalSignature | x509.KeyUsageKeyEncipherment
+	}
+	if options.IsCA {
+		keyUsage |= x509.KeyUsageCertSign | x509.KeyUsageCRLSign
+	}
+
+	template := &x509.Certificate{
+		SerialNumber:          serialNumber,
+		NotBefore:             notBefore,
+		NotAfter:              notAfter,
+		KeyUsage:              keyUsage,
+		ExtKeyUsage:           options.ExtKeyUsage,
+		BasicConstraintsValid: true,
+		IsCA:                  options.IsCA,
+	}
+	if options.Org != "" {
+		template.Subject = pkix.Name{Organization: []string{options.Org}}
+	}
+
+	if h := options.Host; len(h) > 0 && options.IsDualUse {
+		cn, err := DualUseCommonName(h)
+		if err != nil {
+			log.Errorf("dual-use failed for self-signed cert template - omitting CN (%v)", err)
+		} else {
+			template.Subject.CommonName = cn
+		}
+	}
+
+	s, err := buildSubjectAltNameExtensionForOptions(options)
+	if err != nil {
+		return nil, err
+	}
+	if s != nil {
+		template.ExtraExtensions = []pkix.Extension{*s}
+	}
+
+	return template, nil
+}
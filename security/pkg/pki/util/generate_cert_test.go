@@ -0,0 +1,103 @@
+// Copyright Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package util
+
+import (
+	"crypto/x509"
+	"encoding/pem"
+	"math/big"
+	"testing"
+	"time"
+)
+
+func TestGenSelfSignedCertDefaults(t *testing.T) {
+	options := CertOptions{
+		Host:     "istio.io",
+		Org:      "Istio Test",
+		ECSigAlg: Ed25519SigAlg,
+	}
+
+	certPEM, keyPEM, err := GenSelfSignedCert(options)
+	if err != nil {
+		t.Fatalf("GenSelfSignedCert failed: %v", err)
+	}
+
+	block, _ := pem.Decode(certPEM)
+	if block == nil || block.Type != blockTypeCertificate {
+		t.Fatalf("expected a %q PEM block, got %v", blockTypeCertificate, block)
+	}
+	cert, err := x509.ParseCertificate(block.Bytes)
+	if err != nil {
+		t.Fatalf("failed to parse generated certificate: %v", err)
+	}
+
+	if cert.IsCA {
+		t.Error("expected IsCA to default to false")
+	}
+	if cert.SerialNumber == nil || cert.SerialNumber.Sign() == 0 {
+		t.Error("expected a random non-zero serial number to be generated")
+	}
+	wantKeyUsage := x509.KeyUsageDigitalSignature | x509.KeyUsageKeyEncipherment
+	if cert.KeyUsage != wantKeyUsage {
+		t.Errorf("got KeyUsage %v, want %v", cert.KeyUsage, wantKeyUsage)
+	}
+	if !cert.NotAfter.After(cert.NotBefore) {
+		t.Errorf("expected NotAfter (%v) to be after NotBefore (%v)", cert.NotAfter, cert.NotBefore)
+	}
+
+	if err := VerifyKeyPair(certPEM, keyPEM); err != nil {
+		t.Errorf("VerifyKeyPair failed: %v", err)
+	}
+}
+
+func TestGenSelfSignedCertCAOptions(t *testing.T) {
+	notBefore := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	notAfter := notBefore.Add(24 * time.Hour)
+	serial := big.NewInt(42)
+
+	options := CertOptions{
+		Org:          "Istio Test",
+		ECSigAlg:     EcdsaSigAlg,
+		ECCCurve:     P256Curve,
+		IsCA:         true,
+		NotBefore:    notBefore,
+		NotAfter:     notAfter,
+		SerialNumber: serial,
+	}
+
+	certPEM, _, err := GenSelfSignedCert(options)
+	if err != nil {
+		t.Fatalf("GenSelfSignedCert failed: %v", err)
+	}
+
+	block, _ := pem.Decode(certPEM)
+	cert, err := x509.ParseCertificate(block.Bytes)
+	if err != nil {
+		t.Fatalf("failed to parse generated certificate: %v", err)
+	}
+
+	if !cert.IsCA {
+		t.Error("expected IsCA to be true")
+	}
+	if cert.KeyUsage&x509.KeyUsageCertSign == 0 || cert.KeyUsage&x509.KeyUsageCRLSign == 0 {
+		t.Errorf("expected KeyUsageCertSign|KeyUsageCRLSign to be set for a CA cert, got %v", cert.KeyUsage)
+	}
+	if !cert.NotBefore.Equal(notBefore) || !cert.NotAfter.Equal(notAfter) {
+		t.Errorf("got validity [%v, %v], want [%v, %v]", cert.NotBefore, cert.NotAfter, notBefore, notAfter)
+	}
+	if cert.SerialNumber.Cmp(serial) != 0 {
+		t.Errorf("got serial number %v, want %v", cert.SerialNumber, serial)
+	}
+}
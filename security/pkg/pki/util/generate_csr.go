@@ -19,13 +19,11 @@
 package util
 
 import (
-	"crypto"
-	"crypto/ecdsa"
 	"crypto/elliptic"
 	"crypto/rand"
-	"crypto/rsa"
 	"crypto/x509"
 	"crypto/x509/pkix"
+	"encoding/pem"
 	"errors"
 	"fmt"
 	"os"
@@ -38,49 +36,61 @@ import (
 // to ensure proper security
 const MinimumRsaKeySize = 2048
 
-// GenCSR generates a X.509 certificate sign request and private key with the given options.
+// ellipticCurve maps a SupportedEllipticCurves value to the corresponding elliptic.Curve,
+// defaulting to P256Curve when curve is empty. It returns an error for any other unrecognized value
+// rather than silently defaulting, so a typo in configuration fails loudly instead of generating a
+// key with a weaker-than-intended curve.
+func ellipticCurve(curve SupportedEllipticCurves) (elliptic.Curve, error) {
+	switch curve {
+	case "", P256Curve:
+		return elliptic.P256(), nil
+	case P384Curve:
+		return elliptic.P384(), nil
+	case P521Curve:
+		return elliptic.P521(), nil
+	case P224Curve:
+		return elliptic.P224(), nil
+	default:
+		return nil, fmt.Errorf("unsupported elliptic curve: %v", curve)
+	}
+}
+
+// GenCSR generates a X.509 certificate sign request and private key with the given options. The
+// private key is generated by options.KeyProvider, or by DefaultKeyProvider when unset, which
+// allows callers to keep the key inside an HSM/KMS instead of generating it in-process.
 func GenCSR(options CertOptions) ([]byte, []byte, error) {
-	var priv any
-	var err error
-	if options.ECSigAlg != "" {
-		switch options.ECSigAlg {
-		case EcdsaSigAlg:
-			var curve elliptic.Curve
-			switch options.ECCCurve {
-			case P384Curve:
-				curve = elliptic.P384()
-			default:
-				curve = elliptic.P256()
-			}
-			priv, err = ecdsa.GenerateKey(curve, rand.Reader)
-			if err != nil {
-				return nil, nil, fmt.Errorf("EC key generation failed (%v)", err)
-			}
-		default:
-			return nil, nil, errors.New("csr cert generation fails due to unsupported EC signature algorithm")
-		}
-	} else {
-		if options.RSAKeySize < MinimumRsaKeySize {
-			return nil, nil, fmt.Errorf("requested key size does not meet the minimum required size of %d (requested: %d)", MinimumRsaKeySize, options.RSAKeySize)
-		}
+	provider := options.KeyProvider
+	if provider == nil {
+		provider = DefaultKeyProvider{}
+	}
 
-		priv, err = rsa.GenerateKey(rand.Reader, options.RSAKeySize)
-		if err != nil {
-			return nil, nil, fmt.Errorf("RSA key generation failed (%v)", err)
-		}
+	signer, handle, err := provider.GenerateKey(options)
+	if err != nil {
+		return nil, nil, err
 	}
+
 	template, err := GenCSRTemplate(options)
 	if err != nil {
 		return nil, nil, fmt.Errorf("CSR template creation failed (%v)", err)
 	}
 
-	csrBytes, err := x509.CreateCertificateRequest(rand.Reader, template, crypto.PrivateKey(priv))
+	csrBytes, err := x509.CreateCertificateRequest(rand.Reader, template, signer)
 	if err != nil {
 		return nil, nil, fmt.Errorf("CSR creation failed (%v)", err)
 	}
+	csrPEM := pem.EncodeToMemory(&pem.Block{Type: blockTypeCertificateRequest, Bytes: csrBytes})
 
-	csr, privKey, err := encodePem(true, csrBytes, priv, options.PKCS8Key)
-	return csr, privKey, err
+	privKey, err := provider.MarshalPrivate(handle)
+	if errors.Is(err, ErrPrivateKeyNotExportable) {
+		// The provider generated the key (e.g. inside an HSM) by design without ever handing us
+		// exportable material. The CSR itself is still valid and signed, so return it with no key.
+		return csrPEM, nil, nil
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return csrPEM, privKey, nil
 }
 
 // GenCSRTemplate generates a certificateRequest template with the given options.
@@ -92,20 +102,21 @@ func GenCSRTemplate(options CertOptions) (*x509.CertificateRequest, error) {
 		}
 	}
 
-	if h := options.Host; len(h) > 0 {
-		s, err := BuildSubjectAltNameExtension(h)
+	if h := options.Host; len(h) > 0 && options.IsDualUse {
+		cn, err := DualUseCommonName(h)
 		if err != nil {
-			return nil, err
-		}
-		if options.IsDualUse {
-			cn, err := DualUseCommonName(h)
-			if err != nil {
-				// log and continue
-				log.Errorf("dual-use failed for CSR template - omitting CN (%v)", err)
-			} else {
-				template.Subject.CommonName = cn
-			}
+			// log and continue
+			log.Errorf("dual-use failed for CSR template - omitting CN (%v)", err)
+		} else {
+			template.Subject.CommonName = cn
 		}
+	}
+
+	s, err := buildSubjectAltNameExtensionForOptions(options)
+	if err != nil {
+		return nil, err
+	}
+	if s != nil {
 		template.ExtraExtensions = []pkix.Extension{*s}
 	}
 
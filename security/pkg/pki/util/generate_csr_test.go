@@ -0,0 +1,66 @@
+// Copyright Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package util
+
+import (
+	"crypto/ed25519"
+	"crypto/x509"
+	"encoding/pem"
+	"testing"
+)
+
+func TestGenCSREd25519(t *testing.T) {
+	options := CertOptions{
+		Host:     "istio.io",
+		Org:      "Istio Test",
+		ECSigAlg: Ed25519SigAlg,
+	}
+
+	csrPEM, keyPEM, err := GenCSR(options)
+	if err != nil {
+		t.Fatalf("GenCSR failed: %v", err)
+	}
+
+	csrBlock, _ := pem.Decode(csrPEM)
+	if csrBlock == nil || csrBlock.Type != blockTypeCertificateRequest {
+		t.Fatalf("expected a %q PEM block, got %v", blockTypeCertificateRequest, csrBlock)
+	}
+	csr, err := x509.ParseCertificateRequest(csrBlock.Bytes)
+	if err != nil {
+		t.Fatalf("failed to parse generated CSR: %v", err)
+	}
+	if _, ok := csr.PublicKey.(ed25519.PublicKey); !ok {
+		t.Errorf("expected an ed25519.PublicKey, got %T", csr.PublicKey)
+	}
+
+	keyBlock, _ := pem.Decode(keyPEM)
+	if keyBlock == nil || keyBlock.Type != blockTypePKCS8PrivateKey {
+		t.Fatalf("expected an Ed25519 key to be PKCS#8-encoded, got %v", keyBlock)
+	}
+	priv, err := x509.ParsePKCS8PrivateKey(keyBlock.Bytes)
+	if err != nil {
+		t.Fatalf("failed to parse generated Ed25519 key: %v", err)
+	}
+	if _, ok := priv.(ed25519.PrivateKey); !ok {
+		t.Errorf("expected an ed25519.PrivateKey, got %T", priv)
+	}
+}
+
+func TestGenCSRUnsupportedSigAlg(t *testing.T) {
+	_, _, err := GenCSR(CertOptions{ECSigAlg: "bogus"})
+	if err == nil {
+		t.Fatal("expected an error for an unsupported signature algorithm, got nil")
+	}
+}
@@ -0,0 +1,110 @@
+// Copyright Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package util
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"errors"
+	"fmt"
+)
+
+// KeyHandle identifies a key previously generated by a KeyProvider. Its concrete type and contents
+// are provider-specific; callers should treat it as opaque and pass it back only to the
+// MarshalPrivate method of the same provider that returned it.
+type KeyHandle any
+
+// ErrPrivateKeyNotExportable is returned by KeyProvider.MarshalPrivate when the provider generated
+// the key in a way that by design never makes the private key material available - for example a
+// key generated inside an HSM or KMS. GenCSR and GenSelfSignedCert treat this as expected rather
+// than fatal: they return the CSR/certificate bytes with a nil key instead of failing outright.
+var ErrPrivateKeyNotExportable = errors.New("private key material is not exportable")
+
+// KeyProvider abstracts where and how the private key backing a CSR is generated and held. The
+// DefaultKeyProvider generates keys in-process, as GenCSR has always done; other implementations
+// (for example a PKCS#11-backed provider) can keep the private key inside an HSM or KMS and hand
+// back only a crypto.Signer usable to sign the CSR.
+type KeyProvider interface {
+	// GenerateKey generates a new private key for the given options and returns a crypto.Signer
+	// that can be used to sign a certificate or CSR, along with a KeyHandle identifying the key
+	// for a later MarshalPrivate call.
+	GenerateKey(options CertOptions) (crypto.Signer, KeyHandle, error)
+
+	// MarshalPrivate returns PEM-encoded private key material for handle, or ErrPrivateKeyNotExportable
+	// if the provider will not, by design, export the private key. Any other error is treated as fatal.
+	MarshalPrivate(handle KeyHandle) ([]byte, error)
+}
+
+// DefaultKeyProvider generates private keys in-process using the Go standard library, matching
+// GenCSR's original behavior. It is used whenever CertOptions.KeyProvider is unset.
+type DefaultKeyProvider struct{}
+
+type defaultKeyHandle struct {
+	priv  any
+	pkcs8 bool
+}
+
+// GenerateKey implements KeyProvider.
+func (DefaultKeyProvider) GenerateKey(options CertOptions) (crypto.Signer, KeyHandle, error) {
+	var priv any
+	var err error
+	if options.ECSigAlg != "" {
+		switch options.ECSigAlg {
+		case EcdsaSigAlg:
+			curve, cErr := ellipticCurve(options.ECCCurve)
+			if cErr != nil {
+				return nil, nil, cErr
+			}
+			priv, err = ecdsa.GenerateKey(curve, rand.Reader)
+			if err != nil {
+				return nil, nil, fmt.Errorf("EC key generation failed (%v)", err)
+			}
+		case Ed25519SigAlg:
+			_, priv, err = ed25519.GenerateKey(rand.Reader)
+			if err != nil {
+				return nil, nil, fmt.Errorf("Ed25519 key generation failed (%v)", err)
+			}
+		default:
+			return nil, nil, errors.New("csr cert generation fails due to unsupported EC signature algorithm")
+		}
+	} else {
+		if options.RSAKeySize < MinimumRsaKeySize {
+			return nil, nil, fmt.Errorf("requested key size does not meet the minimum required size of %d (requested: %d)",
+				MinimumRsaKeySize, options.RSAKeySize)
+		}
+		priv, err = rsa.GenerateKey(rand.Reader, options.RSAKeySize)
+		if err != nil {
+			return nil, nil, fmt.Errorf("RSA key generation failed (%v)", err)
+		}
+	}
+
+	signer, ok := priv.(crypto.Signer)
+	if !ok {
+		return nil, nil, fmt.Errorf("generated key of type %T is not a crypto.Signer", priv)
+	}
+	return signer, defaultKeyHandle{priv: priv, pkcs8: options.PKCS8Key}, nil
+}
+
+// MarshalPrivate implements KeyProvider.
+func (DefaultKeyProvider) MarshalPrivate(handle KeyHandle) ([]byte, error) {
+	h, ok := handle.(defaultKeyHandle)
+	if !ok {
+		return nil, fmt.Errorf("DefaultKeyProvider: unrecognized key handle type %T", handle)
+	}
+	return marshalPrivateKeyPem(h.priv, h.pkcs8)
+}
@@ -0,0 +1,80 @@
+// Copyright Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build pkcs11
+
+package util
+
+import (
+	"crypto"
+	"crypto/elliptic"
+	"fmt"
+
+	"github.com/ThalesIgnite/crypto11"
+)
+
+// PKCS11KeyProvider generates keys inside a PKCS#11-compliant HSM or software token, using
+// crypto11 to bridge the PKCS#11 session to a crypto.Signer. It is gated behind the "pkcs11"
+// build tag so that the cgo and dynamic PKCS#11 module dependency it pulls in is opt-in.
+//
+// Requires github.com/ThalesIgnite/crypto11 v1.0.3 (or another v1.0.x release); v1.6.5 and later
+// require Go >= 1.25, newer than this module's go1.21 toolchain.
+//
+// Unlike DefaultKeyProvider, MarshalPrivate always returns ErrPrivateKeyNotExportable: a private
+// key generated inside an HSM is not exportable by design, so callers that configure a
+// PKCS11KeyProvider only ever get the CSR/certificate bytes back, with a nil key.
+type PKCS11KeyProvider struct {
+	// Context is the crypto11 session used to generate and look up keys in the HSM.
+	Context *crypto11.Context
+
+	// KeyLabel is the PKCS#11 CKA_LABEL assigned to generated keys.
+	KeyLabel string
+}
+
+type pkcs11KeyHandle struct {
+	label string
+}
+
+// GenerateKey implements KeyProvider.
+func (p *PKCS11KeyProvider) GenerateKey(options CertOptions) (crypto.Signer, KeyHandle, error) {
+	id := []byte(p.KeyLabel)
+	label := []byte(p.KeyLabel)
+
+	var signer crypto.Signer
+	var err error
+	switch options.ECSigAlg {
+	case "":
+		signer, err = p.Context.GenerateRSAKeyPairWithLabel(id, label, options.RSAKeySize)
+	case EcdsaSigAlg:
+		var curve elliptic.Curve
+		curve, err = ellipticCurve(options.ECCCurve)
+		if err == nil {
+			signer, err = p.Context.GenerateECDSAKeyPairWithLabel(id, label, curve)
+		}
+	default:
+		return nil, nil, fmt.Errorf("PKCS11KeyProvider: unsupported EC signature algorithm: %v", options.ECSigAlg)
+	}
+	if err != nil {
+		return nil, nil, fmt.Errorf("PKCS#11 key generation failed (%v)", err)
+	}
+
+	return signer, pkcs11KeyHandle{label: p.KeyLabel}, nil
+}
+
+// MarshalPrivate implements KeyProvider. HSM-resident keys are never exportable, so this always
+// returns ErrPrivateKeyNotExportable; callers (GenCSR, GenSelfSignedCert) treat that as expected
+// and return the CSR/certificate bytes with a nil key rather than failing.
+func (p *PKCS11KeyProvider) MarshalPrivate(KeyHandle) ([]byte, error) {
+	return nil, ErrPrivateKeyNotExportable
+}
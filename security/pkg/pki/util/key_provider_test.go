@@ -0,0 +1,111 @@
+// Copyright Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package util
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/pem"
+	"testing"
+)
+
+func TestDefaultKeyProviderGenerateKey(t *testing.T) {
+	cases := []struct {
+		name          string
+		options       CertOptions
+		wantBlockType string
+	}{
+		{name: "RSA", options: CertOptions{RSAKeySize: MinimumRsaKeySize}, wantBlockType: blockTypeRSAPrivateKey},
+		{name: "RSA PKCS8", options: CertOptions{RSAKeySize: MinimumRsaKeySize, PKCS8Key: true}, wantBlockType: blockTypePKCS8PrivateKey},
+		{name: "ECDSA", options: CertOptions{ECSigAlg: EcdsaSigAlg, ECCCurve: P256Curve}, wantBlockType: blockTypeECPrivateKey},
+		{name: "Ed25519 is always PKCS8", options: CertOptions{ECSigAlg: Ed25519SigAlg}, wantBlockType: blockTypePKCS8PrivateKey},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			provider := DefaultKeyProvider{}
+
+			signer, handle, err := provider.GenerateKey(c.options)
+			if err != nil {
+				t.Fatalf("GenerateKey failed: %v", err)
+			}
+			if signer == nil {
+				t.Fatal("expected a non-nil crypto.Signer")
+			}
+
+			keyPEM, err := provider.MarshalPrivate(handle)
+			if err != nil {
+				t.Fatalf("MarshalPrivate failed: %v", err)
+			}
+			block, _ := pem.Decode(keyPEM)
+			if block == nil {
+				t.Fatal("MarshalPrivate did not return a PEM block")
+			}
+			if block.Type != c.wantBlockType {
+				t.Errorf("got PEM block type %q, want %q", block.Type, c.wantBlockType)
+			}
+		})
+	}
+}
+
+func TestDefaultKeyProviderMarshalPrivateRejectsForeignHandle(t *testing.T) {
+	_, err := (DefaultKeyProvider{}).MarshalPrivate("not a handle")
+	if err == nil {
+		t.Fatal("expected an error for an unrecognized key handle, got nil")
+	}
+}
+
+// nonExportableKeyProvider stands in for an HSM/KMS-backed provider like PKCS11KeyProvider:
+// GenerateKey succeeds, but MarshalPrivate always reports that the key can't be exported.
+type nonExportableKeyProvider struct{}
+
+func (nonExportableKeyProvider) GenerateKey(CertOptions) (crypto.Signer, KeyHandle, error) {
+	_, priv, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		return nil, nil, err
+	}
+	return priv, nil, nil
+}
+
+func (nonExportableKeyProvider) MarshalPrivate(KeyHandle) ([]byte, error) {
+	return nil, ErrPrivateKeyNotExportable
+}
+
+func TestGenCSRWithNonExportableKeyProvider(t *testing.T) {
+	csrPEM, keyPEM, err := GenCSR(CertOptions{Host: "istio.io", KeyProvider: nonExportableKeyProvider{}})
+	if err != nil {
+		t.Fatalf("GenCSR failed: %v", err)
+	}
+	if len(csrPEM) == 0 {
+		t.Error("expected non-empty CSR bytes even though the key is not exportable")
+	}
+	if keyPEM != nil {
+		t.Errorf("expected a nil key when the provider reports ErrPrivateKeyNotExportable, got %q", keyPEM)
+	}
+}
+
+func TestGenSelfSignedCertWithNonExportableKeyProvider(t *testing.T) {
+	certPEM, keyPEM, err := GenSelfSignedCert(CertOptions{Host: "istio.io", KeyProvider: nonExportableKeyProvider{}})
+	if err != nil {
+		t.Fatalf("GenSelfSignedCert failed: %v", err)
+	}
+	if len(certPEM) == 0 {
+		t.Error("expected non-empty certificate bytes even though the key is not exportable")
+	}
+	if keyPEM != nil {
+		t.Errorf("expected a nil key when the provider reports ErrPrivateKeyNotExportable, got %q", keyPEM)
+	}
+}
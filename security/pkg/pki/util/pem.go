@@ -0,0 +1,60 @@
+// Copyright Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package util
+
+import (
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"fmt"
+)
+
+const (
+	blockTypeCertificate        = "CERTIFICATE"
+	blockTypeCertificateRequest = "CERTIFICATE REQUEST"
+	blockTypeRSAPrivateKey      = "RSA PRIVATE KEY"
+	blockTypeECPrivateKey       = "EC PRIVATE KEY"
+	blockTypePKCS8PrivateKey    = "PRIVATE KEY"
+)
+
+// marshalPrivateKeyPem PEM-encodes priv, which may be an *rsa.PrivateKey, *ecdsa.PrivateKey, or
+// ed25519.PrivateKey. Unless pkcs8 is set, the key is encoded in its type-specific format
+// (PKCS#1 for RSA, SEC1 for ECDSA); ed25519.PrivateKey has no such format, so it is always encoded
+// as PKCS#8 regardless of pkcs8.
+func marshalPrivateKeyPem(priv any, pkcs8 bool) ([]byte, error) {
+	if _, isEd25519 := priv.(ed25519.PrivateKey); pkcs8 || isEd25519 {
+		encoded, err := x509.MarshalPKCS8PrivateKey(priv)
+		if err != nil {
+			return nil, fmt.Errorf("PKCS8 private key marshaling failed (%v)", err)
+		}
+		return pem.EncodeToMemory(&pem.Block{Type: blockTypePKCS8PrivateKey, Bytes: encoded}), nil
+	}
+
+	switch key := priv.(type) {
+	case *rsa.PrivateKey:
+		encoded := x509.MarshalPKCS1PrivateKey(key)
+		return pem.EncodeToMemory(&pem.Block{Type: blockTypeRSAPrivateKey, Bytes: encoded}), nil
+	case *ecdsa.PrivateKey:
+		encoded, err := x509.MarshalECPrivateKey(key)
+		if err != nil {
+			return nil, fmt.Errorf("EC private key marshaling failed (%v)", err)
+		}
+		return pem.EncodeToMemory(&pem.Block{Type: blockTypeECPrivateKey, Bytes: encoded}), nil
+	default:
+		return nil, fmt.Errorf("unsupported private key type %T", priv)
+	}
+}
@@ -0,0 +1,140 @@
+// Copyright Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package util
+
+import (
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"fmt"
+	"net"
+	"net/url"
+	"strings"
+)
+
+// subjectAltNameOID is the OID for the X.509 SubjectAlternativeName extension.
+var subjectAltNameOID = asn1.ObjectIdentifier{2, 5, 29, 17}
+
+// GeneralName tags, as defined in RFC 5280 section 4.2.1.6.
+const (
+	nameTypeEmail = 1
+	nameTypeDNS   = 2
+	nameTypeURI   = 6
+	nameTypeIP    = 7
+)
+
+// BuildSubjectAltNameExtension builds a SAN extension whose contents are inferred from the shape of
+// each comma-separated entry in hosts: entries that parse as an IP become iPAddress SANs, entries
+// containing "://" become uniformResourceIdentifier SANs, entries containing "@" become rfc822Name
+// SANs, and everything else becomes a dNSName SAN.
+func BuildSubjectAltNameExtension(hosts string) (*pkix.Extension, error) {
+	dnsNames, emails, ips, uris, err := parseHostsSAN(hosts)
+	if err != nil {
+		return nil, err
+	}
+	return buildSANExtension(dnsNames, emails, ips, uris)
+}
+
+// parseHostsSAN splits the comma-separated hosts string and infers a SAN type for each entry from
+// its shape: entries that parse as an IP become iPAddress SANs, entries containing "://" become
+// uniformResourceIdentifier SANs, entries containing "@" become rfc822Name SANs, and everything
+// else becomes a dNSName SAN.
+func parseHostsSAN(hosts string) (dnsNames, emails []string, ips []net.IP, uris []*url.URL, err error) {
+	for _, host := range strings.Split(hosts, ",") {
+		host = strings.TrimSpace(host)
+		if host == "" {
+			continue
+		}
+		switch {
+		case strings.Contains(host, "://"):
+			uri, err := url.Parse(host)
+			if err != nil {
+				return nil, nil, nil, nil, fmt.Errorf("failed to parse %q as a URI SAN: %v", host, err)
+			}
+			uris = append(uris, uri)
+		case net.ParseIP(host) != nil:
+			ips = append(ips, net.ParseIP(host))
+		case strings.Contains(host, "@"):
+			emails = append(emails, host)
+		default:
+			dnsNames = append(dnsNames, host)
+		}
+	}
+	return dnsNames, emails, ips, uris, nil
+}
+
+// buildSubjectAltNameExtensionForOptions builds the SAN extension for options, merging the SANs
+// inferred from options.Host with the explicit URIs/EmailAddresses/IPAddresses/DNSNames fields. It
+// returns a nil extension, with no error, when options carries no SAN information at all.
+func buildSubjectAltNameExtensionForOptions(options CertOptions) (*pkix.Extension, error) {
+	var dnsNames, emails []string
+	var ips []net.IP
+	var uris []*url.URL
+
+	if h := options.Host; len(h) > 0 {
+		var err error
+		dnsNames, emails, ips, uris, err = parseHostsSAN(h)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	dnsNames = append(dnsNames, options.DNSNames...)
+	emails = append(emails, options.EmailAddresses...)
+	ips = append(ips, options.IPAddresses...)
+	uris = append(uris, options.URIs...)
+
+	if len(dnsNames) == 0 && len(emails) == 0 && len(ips) == 0 && len(uris) == 0 {
+		return nil, nil
+	}
+	return buildSANExtension(dnsNames, emails, ips, uris)
+}
+
+// buildSANExtension marshals the given SAN fields into a single SubjectAlternativeName extension,
+// following the GeneralName ASN.1 encoding from RFC 5280 section 4.2.1.6.
+func buildSANExtension(dnsNames, emails []string, ips []net.IP, uris []*url.URL) (*pkix.Extension, error) {
+	var rawValues []asn1.RawValue
+	for _, name := range dnsNames {
+		rawValues = append(rawValues, asn1.RawValue{Tag: nameTypeDNS, Class: asn1.ClassContextSpecific, Bytes: []byte(name)})
+	}
+	for _, email := range emails {
+		rawValues = append(rawValues, asn1.RawValue{Tag: nameTypeEmail, Class: asn1.ClassContextSpecific, Bytes: []byte(email)})
+	}
+	for _, ip := range ips {
+		ipBytes := ip.To4()
+		if ipBytes == nil {
+			ipBytes = ip.To16()
+		}
+		rawValues = append(rawValues, asn1.RawValue{Tag: nameTypeIP, Class: asn1.ClassContextSpecific, Bytes: ipBytes})
+	}
+	for _, uri := range uris {
+		rawValues = append(rawValues, asn1.RawValue{Tag: nameTypeURI, Class: asn1.ClassContextSpecific, Bytes: []byte(uri.String())})
+	}
+
+	der, err := asn1.Marshal(rawValues)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal SAN extension: %v", err)
+	}
+	return &pkix.Extension{Id: subjectAltNameOID, Critical: false, Value: der}, nil
+}
+
+// DualUseCommonName returns the first host in a comma-separated host list, for use as the
+// CommonName of a dual-use (client and server) certificate.
+func DualUseCommonName(hosts string) (string, error) {
+	first := strings.TrimSpace(strings.Split(hosts, ",")[0])
+	if first == "" {
+		return "", fmt.Errorf("failed to get a common name from host list %q", hosts)
+	}
+	return first, nil
+}
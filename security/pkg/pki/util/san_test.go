@@ -0,0 +1,102 @@
+// Copyright Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package util
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"net"
+	"net/url"
+	"testing"
+)
+
+func TestBuildSubjectAltNameExtensionForOptionsMergesHostAndStructuredFields(t *testing.T) {
+	spiffeURI, err := url.Parse("spiffe://cluster.local/ns/default/sa/foo")
+	if err != nil {
+		t.Fatalf("failed to parse test SPIFFE URI: %v", err)
+	}
+
+	options := CertOptions{
+		Host:           "foo.example.com,10.0.0.1",
+		DNSNames:       []string{"bar.example.com"},
+		EmailAddresses: []string{"admin@example.com"},
+		IPAddresses:    []net.IP{net.ParseIP("10.0.0.2")},
+		URIs:           []*url.URL{spiffeURI},
+	}
+
+	ext, err := buildSubjectAltNameExtensionForOptions(options)
+	if err != nil {
+		t.Fatalf("buildSubjectAltNameExtensionForOptions failed: %v", err)
+	}
+	if ext == nil {
+		t.Fatal("expected a non-nil SAN extension")
+	}
+
+	// x509.CertificateRequest understands the SAN extension shape, so round-trip through it to
+	// assert on the merged contents without hand-decoding the extension ourselves.
+	_, signer, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatalf("failed to generate a signer for the SAN assertions: %v", err)
+	}
+
+	template := &x509.CertificateRequest{ExtraExtensions: []pkix.Extension{*ext}}
+	csrDER, err := x509.CreateCertificateRequest(rand.Reader, template, signer)
+	if err != nil {
+		t.Fatalf("failed to build a CSR for the SAN assertions: %v", err)
+	}
+	csr, err := x509.ParseCertificateRequest(csrDER)
+	if err != nil {
+		t.Fatalf("failed to parse the CSR for the SAN assertions: %v", err)
+	}
+
+	wantDNSNames := []string{"foo.example.com", "bar.example.com"}
+	if !equalStrings(csr.DNSNames, wantDNSNames) {
+		t.Errorf("got DNSNames %v, want %v", csr.DNSNames, wantDNSNames)
+	}
+	wantEmails := []string{"admin@example.com"}
+	if !equalStrings(csr.EmailAddresses, wantEmails) {
+		t.Errorf("got EmailAddresses %v, want %v", csr.EmailAddresses, wantEmails)
+	}
+	if len(csr.IPAddresses) != 2 {
+		t.Errorf("got %d IPAddresses, want 2", len(csr.IPAddresses))
+	}
+	if len(csr.URIs) != 1 || csr.URIs[0].String() != spiffeURI.String() {
+		t.Errorf("got URIs %v, want [%v]", csr.URIs, spiffeURI)
+	}
+}
+
+func TestBuildSubjectAltNameExtensionForOptionsNoSANs(t *testing.T) {
+	ext, err := buildSubjectAltNameExtensionForOptions(CertOptions{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if ext != nil {
+		t.Errorf("expected a nil extension when no SAN information is set, got %v", ext)
+	}
+}
+
+func equalStrings(got, want []string) bool {
+	if len(got) != len(want) {
+		return false
+	}
+	for i := range got {
+		if got[i] != want[i] {
+			return false
+		}
+	}
+	return true
+}
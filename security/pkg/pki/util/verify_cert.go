@@ -0,0 +1,95 @@
+// Copyright Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package util
+
+import (
+	"crypto"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"fmt"
+)
+
+// VerifyKeyPair parses certPEM and keyPEM and checks that the certificate's public key matches the
+// public half of the private key. It supports RSA, ECDSA, and Ed25519 keys, and returns an error if
+// either input fails to parse or the keys don't correspond.
+func VerifyKeyPair(certPEM, keyPEM []byte) error {
+	cert, err := parseCertificatePem(certPEM)
+	if err != nil {
+		return err
+	}
+
+	priv, err := parsePrivateKeyPem(keyPEM)
+	if err != nil {
+		return err
+	}
+
+	signer, ok := priv.(crypto.Signer)
+	if !ok {
+		return fmt.Errorf("private key of type %T does not implement crypto.Signer", priv)
+	}
+
+	if !publicKeysEqual(cert.PublicKey, signer.Public()) {
+		return errors.New("certificate public key does not match private key")
+	}
+	return nil
+}
+
+// parseCertificatePem decodes a single PEM-encoded certificate.
+func parseCertificatePem(certPEM []byte) (*x509.Certificate, error) {
+	block, _ := pem.Decode(certPEM)
+	if block == nil {
+		return nil, errors.New("failed to decode certificate PEM")
+	}
+	cert, err := x509.ParseCertificate(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse certificate (%v)", err)
+	}
+	return cert, nil
+}
+
+// parsePrivateKeyPem decodes a single PEM-encoded private key, in any of the formats
+// marshalPrivateKeyPem can produce: PKCS#1 (RSA), SEC1 (EC), or PKCS#8 (RSA, EC, or Ed25519).
+func parsePrivateKeyPem(keyPEM []byte) (any, error) {
+	block, _ := pem.Decode(keyPEM)
+	if block == nil {
+		return nil, errors.New("failed to decode private key PEM")
+	}
+
+	switch block.Type {
+	case blockTypeRSAPrivateKey:
+		return x509.ParsePKCS1PrivateKey(block.Bytes)
+	case blockTypeECPrivateKey:
+		return x509.ParseECPrivateKey(block.Bytes)
+	case blockTypePKCS8PrivateKey:
+		return x509.ParsePKCS8PrivateKey(block.Bytes)
+	default:
+		return nil, fmt.Errorf("unsupported private key PEM block type %q", block.Type)
+	}
+}
+
+// publicKeysEqual reports whether a and b are the same public key. *rsa.PublicKey, *ecdsa.PublicKey,
+// and ed25519.PublicKey all implement Equal(crypto.PublicKey) bool, which this relies on instead of
+// switching on concrete type so that new KeyProvider-backed key types are covered automatically.
+func publicKeysEqual(a, b crypto.PublicKey) bool {
+	type equaler interface {
+		Equal(x crypto.PublicKey) bool
+	}
+	ea, ok := a.(equaler)
+	if !ok {
+		return false
+	}
+	return ea.Equal(b)
+}
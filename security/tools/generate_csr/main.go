@@ -0,0 +1,62 @@
+// Copyright Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// generate_csr generates a PEM-encoded X.509 certificate signing request and private key.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"istio.io/istio/security/pkg/pki/util"
+)
+
+var (
+	host       = flag.String("host", "", "Comma-separated hostnames and IPs to generate a certificate for")
+	org        = flag.String("organization", "Istio", "Organization for the certificate")
+	rsaKeySize = flag.Int("rsa-key-size", 2048, "Size of the RSA private key to generate")
+	sigAlg     = flag.String("sig-alg", "", "Signature algorithm to use: ECDSA or ED25519. Defaults to RSA when unset")
+	eccCurve   = flag.String("ec-curve", string(util.P256Curve), "Elliptic curve to use when sig-alg is ECDSA: P224, P256, P384, or P521")
+	dualUse    = flag.Bool("dual-use", false, "Generate the CSR for dual-use (client and server) mode")
+	pkcs8      = flag.Bool("pkcs8", false, "Encode the private key using PKCS#8")
+)
+
+func main() {
+	flag.Parse()
+
+	options := util.CertOptions{
+		Host:       *host,
+		Org:        *org,
+		RSAKeySize: *rsaKeySize,
+		ECSigAlg:   util.SupportedECSignatureAlgorithms(*sigAlg),
+		ECCCurve:   util.SupportedEllipticCurves(*eccCurve),
+		IsDualUse:  *dualUse,
+		PKCS8Key:   *pkcs8,
+	}
+
+	if err := util.ValidateCertOptions(options); err != nil {
+		fmt.Fprintf(os.Stderr, "invalid options: %v\n", err)
+		os.Exit(1)
+	}
+
+	csr, priv, err := util.GenCSR(options)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "failed to generate CSR: %v\n", err)
+		os.Exit(1)
+	}
+
+	fmt.Print(string(csr))
+	fmt.Print(string(priv))
+}